@@ -0,0 +1,155 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package identity
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/go-azure-helpers/resourcemanager/commonids"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// expandUserAssignedIdentityIds turns a list of User Assigned Identity ID strings into a map, ready
+// to be sent to the API - the values are intentionally left empty since the API only expects the
+// keys (the User Assigned Identity IDs) to be sent on the way up.
+func expandUserAssignedIdentityIds(input []string) map[string]UserAssignedIdentityDetails {
+	identityIds := make(map[string]UserAssignedIdentityDetails, len(input))
+	for _, v := range input {
+		identityIds[v] = UserAssignedIdentityDetails{
+			// intentionally empty since the expand shouldn't send these values
+		}
+	}
+	return identityIds
+}
+
+// canonicalizeIdentityIds re-keys the map of User Assigned Identity IDs returned from the API using
+// the canonical casing of each Resource ID, whilst preserving the `principalId`/`clientId` values
+// returned for each identity - so that drift isn't reported purely because Azure returned a
+// differently-cased Resource ID, and so that per-identity computed values survive into state.
+func canonicalizeIdentityIds(input map[string]UserAssignedIdentityDetails) (map[string]UserAssignedIdentityDetails, error) {
+	output := make(map[string]UserAssignedIdentityDetails, len(input))
+
+	for raw, details := range input {
+		id, err := commonids.ParseUserAssignedIdentityIDInsensitively(raw)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %q as a User Assigned Identity ID: %+v", raw, err)
+		}
+
+		output[id.ID()] = details
+	}
+
+	return output, nil
+}
+
+// flattenIdentityIds returns the list of User Assigned Identity IDs contained within the given map,
+// for use as the `identity_ids` value in the flattened schema block. The IDs are returned in map
+// iteration order, which is not defined.
+func flattenIdentityIds(input map[string]UserAssignedIdentityDetails) []string {
+	identityIds := make([]string, 0, len(input))
+	for id := range input {
+		identityIds = append(identityIds, id)
+	}
+	return identityIds
+}
+
+// flattenIdentityIdsDetails turns the map of User Assigned Identity IDs returned from the API into
+// the `identity_ids_details` value for the flattened schema block, preserving the `principalId`/
+// `clientId` values Azure returned for each individual identity rather than discarding them.
+// The result is sorted by `identity_id` since, unlike `identity_ids`, this field is a TypeList and
+// so its element order is diff-significant - ranging over the input map directly would otherwise
+// produce a randomized order on every read.
+func flattenIdentityIdsDetails(input map[string]UserAssignedIdentityDetails) []interface{} {
+	identityIds := flattenIdentityIds(input)
+	sort.Strings(identityIds)
+
+	identityValues := make([]interface{}, 0, len(identityIds))
+	for _, id := range identityIds {
+		details := input[id]
+		clientId := ""
+		if details.ClientId != nil {
+			clientId = *details.ClientId
+		}
+		principalId := ""
+		if details.PrincipalId != nil {
+			principalId = *details.PrincipalId
+		}
+
+		identityValues = append(identityValues, map[string]interface{}{
+			"identity_id":  id,
+			"client_id":    clientId,
+			"principal_id": principalId,
+		})
+	}
+	return identityValues
+}
+
+// flattenIdentityIdsDetailsToModel is the typed-schema equivalent of flattenIdentityIdsDetails, and
+// is sorted by `identity_id` for the same reason.
+func flattenIdentityIdsDetailsToModel(input map[string]UserAssignedIdentityDetails) []UserAssignedIdentityValue {
+	identityIds := flattenIdentityIds(input)
+	sort.Strings(identityIds)
+
+	identityValues := make([]UserAssignedIdentityValue, 0, len(identityIds))
+	for _, id := range identityIds {
+		details := input[id]
+		clientId := ""
+		if details.ClientId != nil {
+			clientId = *details.ClientId
+		}
+		principalId := ""
+		if details.PrincipalId != nil {
+			principalId = *details.PrincipalId
+		}
+
+		identityValues = append(identityValues, UserAssignedIdentityValue{
+			IdentityId:  id,
+			ClientId:    clientId,
+			PrincipalId: principalId,
+		})
+	}
+	return identityValues
+}
+
+// identityIdsDetailsSchema returns the shared, always-Computed `identity_ids_details` attribute
+// exposed by every map type in this package that supports User Assigned Identities, surfacing the
+// per-identity `principal_id`/`client_id` values Azure returns alongside `identity_ids`.
+func identityIdsDetailsSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Computed: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"identity_id": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"client_id": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"principal_id": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+			},
+		},
+	}
+}
+
+// identityIdsFromSchema extracts the `identity_ids` Set from a raw `identity` block as a list of
+// strings, returning an empty slice when the block (or the field) is absent.
+func identityIdsFromSchema(raw map[string]interface{}) []string {
+	v, ok := raw["identity_ids"]
+	if !ok {
+		return []string{}
+	}
+
+	rawIds := v.(*schema.Set).List()
+	identityIds := make([]string, 0, len(rawIds))
+	for _, id := range rawIds {
+		identityIds = append(identityIds, id.(string))
+	}
+	return identityIds
+}
@@ -0,0 +1,172 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package identity
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func pointerTo(input string) *string {
+	return &input
+}
+
+func TestExpandUserAssignedIdentityIds(t *testing.T) {
+	actual := expandUserAssignedIdentityIds([]string{"/subscriptions/12345/id1", "/subscriptions/12345/id2"})
+
+	expected := map[string]UserAssignedIdentityDetails{
+		"/subscriptions/12345/id1": {},
+		"/subscriptions/12345/id2": {},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("expected %+v but got %+v", expected, actual)
+	}
+}
+
+func TestExpandUserAssignedIdentityIdsEmpty(t *testing.T) {
+	actual := expandUserAssignedIdentityIds(nil)
+	if len(actual) != 0 {
+		t.Fatalf("expected an empty map but got %+v", actual)
+	}
+}
+
+func TestCanonicalizeIdentityIdsPreservesDetails(t *testing.T) {
+	const canonicalId = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/group1/providers/Microsoft.ManagedIdentity/userAssignedIdentities/id1"
+
+	input := map[string]UserAssignedIdentityDetails{
+		canonicalId: {
+			ClientId:    pointerTo("client-1"),
+			PrincipalId: pointerTo("principal-1"),
+		},
+	}
+
+	actual, err := canonicalizeIdentityIds(input)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if len(actual) != 1 {
+		t.Fatalf("expected a single identity but got %+v", actual)
+	}
+
+	var details UserAssignedIdentityDetails
+	for _, v := range actual {
+		details = v
+	}
+	if details.ClientId == nil || *details.ClientId != "client-1" {
+		t.Fatalf("expected ClientId to be preserved, got %+v", details)
+	}
+	if details.PrincipalId == nil || *details.PrincipalId != "principal-1" {
+		t.Fatalf("expected PrincipalId to be preserved, got %+v", details)
+	}
+}
+
+func TestCanonicalizeIdentityIdsInvalidId(t *testing.T) {
+	if _, err := canonicalizeIdentityIds(map[string]UserAssignedIdentityDetails{"": {}}); err == nil {
+		t.Fatalf("expected an error parsing an empty User Assigned Identity ID but didn't get one")
+	}
+}
+
+func TestFlattenIdentityIdsDetails(t *testing.T) {
+	input := map[string]UserAssignedIdentityDetails{
+		"/subscriptions/12345/id1": {
+			ClientId:    pointerTo("client-1"),
+			PrincipalId: pointerTo("principal-1"),
+		},
+		"/subscriptions/12345/id2": {
+			// intentionally nil - the API doesn't always return every value
+		},
+	}
+
+	actual := flattenIdentityIdsDetails(input)
+	if len(actual) != 2 {
+		t.Fatalf("expected 2 entries but got %d: %+v", len(actual), actual)
+	}
+
+	byId := make(map[string]map[string]interface{})
+	for _, raw := range actual {
+		v := raw.(map[string]interface{})
+		byId[v["identity_id"].(string)] = v
+	}
+
+	if byId["/subscriptions/12345/id1"]["client_id"] != "client-1" {
+		t.Fatalf("expected client_id to be populated for id1: %+v", byId["/subscriptions/12345/id1"])
+	}
+	if byId["/subscriptions/12345/id1"]["principal_id"] != "principal-1" {
+		t.Fatalf("expected principal_id to be populated for id1: %+v", byId["/subscriptions/12345/id1"])
+	}
+	if byId["/subscriptions/12345/id2"]["client_id"] != "" {
+		t.Fatalf("expected client_id to default to an empty string for id2: %+v", byId["/subscriptions/12345/id2"])
+	}
+}
+
+func TestFlattenIdentityIdsDetailsOrderIsStable(t *testing.T) {
+	input := map[string]UserAssignedIdentityDetails{
+		"/subscriptions/12345/id4": {ClientId: pointerTo("client-4")},
+		"/subscriptions/12345/id2": {ClientId: pointerTo("client-2")},
+		"/subscriptions/12345/id3": {ClientId: pointerTo("client-3")},
+		"/subscriptions/12345/id1": {ClientId: pointerTo("client-1")},
+	}
+	expected := []string{
+		"/subscriptions/12345/id1",
+		"/subscriptions/12345/id2",
+		"/subscriptions/12345/id3",
+		"/subscriptions/12345/id4",
+	}
+
+	for i := 0; i < 10; i++ {
+		actual := flattenIdentityIdsDetails(input)
+		if len(actual) != len(expected) {
+			t.Fatalf("expected %d entries but got %d: %+v", len(expected), len(actual), actual)
+		}
+		for idx, raw := range actual {
+			v := raw.(map[string]interface{})
+			if v["identity_id"].(string) != expected[idx] {
+				t.Fatalf("run %d: expected identity_id %d to be %q but got %+v", i, idx, expected[idx], actual)
+			}
+		}
+	}
+}
+
+func TestFlattenIdentityIdsDetailsToModelOrderIsStable(t *testing.T) {
+	input := map[string]UserAssignedIdentityDetails{
+		"/subscriptions/12345/id4": {ClientId: pointerTo("client-4")},
+		"/subscriptions/12345/id2": {ClientId: pointerTo("client-2")},
+		"/subscriptions/12345/id3": {ClientId: pointerTo("client-3")},
+		"/subscriptions/12345/id1": {ClientId: pointerTo("client-1")},
+	}
+	expected := []string{
+		"/subscriptions/12345/id1",
+		"/subscriptions/12345/id2",
+		"/subscriptions/12345/id3",
+		"/subscriptions/12345/id4",
+	}
+
+	for i := 0; i < 10; i++ {
+		actual := flattenIdentityIdsDetailsToModel(input)
+		if len(actual) != len(expected) {
+			t.Fatalf("expected %d entries but got %d: %+v", len(expected), len(actual), actual)
+		}
+		for idx, v := range actual {
+			if v.IdentityId != expected[idx] {
+				t.Fatalf("run %d: expected identity_id %d to be %q but got %+v", i, idx, expected[idx], actual)
+			}
+		}
+	}
+}
+
+func TestFlattenIdentityIds(t *testing.T) {
+	input := map[string]UserAssignedIdentityDetails{
+		"/subscriptions/12345/id1": {},
+		"/subscriptions/12345/id2": {},
+	}
+
+	actual := flattenIdentityIds(input)
+	sort.Strings(actual)
+
+	expected := []string{"/subscriptions/12345/id1", "/subscriptions/12345/id2"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("expected %+v but got %+v", expected, actual)
+	}
+}
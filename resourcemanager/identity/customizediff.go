@@ -0,0 +1,126 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package identity
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// SystemOrSingleUserAssignedDiff returns a CustomizeDiffFunc which validates, at plan time, that the
+// `identity_ids` constraints enforced by ExpandSystemOrSingleUserAssignedMap are satisfied - namely
+// that `identity_ids` is only set when `type` is `UserAssigned`, and that exactly one ID is specified
+// in that case.
+func SystemOrSingleUserAssignedDiff(fieldName string) schema.CustomizeDiffFunc {
+	return func(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+		return validateIdentityIdsCount(d, fieldName, 1, TypeUserAssigned)
+	}
+}
+
+// SystemAndSingleUserAssignedDiff returns a CustomizeDiffFunc which validates, at plan time, that
+// `identity_ids` contains exactly one value when `type` is `SystemAssigned, UserAssigned`.
+func SystemAndSingleUserAssignedDiff(fieldName string) schema.CustomizeDiffFunc {
+	return func(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+		return validateIdentityIdsCount(d, fieldName, 1, TypeSystemAssignedUserAssigned)
+	}
+}
+
+// SystemOrMultipleUserAssignedDiff returns a CustomizeDiffFunc which validates, at plan time, that
+// `identity_ids` is set when, and only when, `type` is `UserAssigned` - at least one ID is then
+// required, with no upper bound on the number of IDs.
+func SystemOrMultipleUserAssignedDiff(fieldName string) schema.CustomizeDiffFunc {
+	return func(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+		return validateIdentityIdsCount(d, fieldName, unboundedIdentityIds, TypeUserAssigned)
+	}
+}
+
+// MultipleUserAssignedDiff returns a CustomizeDiffFunc which validates, at plan time, that
+// `identity_ids` is set when `type` is `UserAssigned` - any number of IDs is permitted.
+func MultipleUserAssignedDiff(fieldName string) schema.CustomizeDiffFunc {
+	return func(_ context.Context, d *schema.ResourceDiff, _ interface{}) error {
+		return validateIdentityIdsCount(d, fieldName, unboundedIdentityIds, TypeUserAssigned)
+	}
+}
+
+// unboundedIdentityIds signals to validateIdentityIdsCount that there's no upper bound on the
+// number of `identity_ids` that may be specified.
+const unboundedIdentityIds = 0
+
+// validateIdentityIdsCount walks the `identity` block at fieldName and delegates to
+// validateIdentityIdsCountForType to confirm the `identity_ids` invariants are satisfied.
+func validateIdentityIdsCount(d *schema.ResourceDiff, fieldName string, maxIdentityIds int, userAssignedTypes ...Type) error {
+	raw, ok := d.GetOk(fieldName)
+	if !ok {
+		return nil
+	}
+
+	identities := raw.([]interface{})
+	if len(identities) == 0 || identities[0] == nil {
+		return nil
+	}
+
+	identity := identities[0].(map[string]interface{})
+	identityType := Type(identity["type"].(string))
+	identityIds := identity["identity_ids"].(*schema.Set).List()
+
+	return validateIdentityIdsCountForType(identityType, len(identityIds), maxIdentityIds, userAssignedTypes)
+}
+
+// validateIdentityIdsCountForType confirms that identityIdsCount is zero unless identityType is one
+// of userAssignedTypes, and (unless maxIdentityIds is unboundedIdentityIds) never exceeds
+// maxIdentityIds. It contains the pure validation logic backing each `*Diff` CustomizeDiffFunc, kept
+// separate from validateIdentityIdsCount so it can be unit tested without a *schema.ResourceDiff.
+func validateIdentityIdsCountForType(identityType Type, identityIdsCount, maxIdentityIds int, userAssignedTypes []Type) error {
+	isUserAssignedType := false
+	for _, t := range userAssignedTypes {
+		if identityType == t {
+			isUserAssignedType = true
+			break
+		}
+	}
+
+	if !isUserAssignedType {
+		if identityIdsCount > 0 {
+			return fmt.Errorf("`identity_ids` cannot be specified when `type` is set to %q", string(identityType))
+		}
+		return nil
+	}
+
+	if identityIdsCount == 0 {
+		return fmt.Errorf("`identity_ids` must be specified when `type` is set to %q", string(identityType))
+	}
+	if maxIdentityIds != unboundedIdentityIds && identityIdsCount > maxIdentityIds {
+		return fmt.Errorf("`identity_ids` can only contain %d identity ID(s) when `type` is set to %q, got %d", maxIdentityIds, string(identityType), identityIdsCount)
+	}
+
+	return nil
+}
+
+// ValidateType returns a ValidateDiagFunc which confirms that the `type` attribute of an `identity`
+// block is one of the supplied, allowed Identity Types.
+func ValidateType(allowed []Type) func(i interface{}, path cty.Path) diag.Diagnostics {
+	return func(i interface{}, path cty.Path) diag.Diagnostics {
+		v, ok := i.(string)
+		if !ok {
+			return diag.Errorf("expected type of %v to be string", path)
+		}
+
+		for _, allowedType := range allowed {
+			if v == string(allowedType) {
+				return nil
+			}
+		}
+
+		allowedStrings := make([]string, 0, len(allowed))
+		for _, allowedType := range allowed {
+			allowedStrings = append(allowedStrings, string(allowedType))
+		}
+
+		return diag.Errorf("expected %v to be one of %v, got %q", path, allowedStrings, v)
+	}
+}
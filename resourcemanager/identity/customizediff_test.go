@@ -0,0 +1,98 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package identity
+
+import (
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+)
+
+func TestValidateIdentityIdsCountForType(t *testing.T) {
+	testCases := []struct {
+		name              string
+		identityType      Type
+		identityIdsCount  int
+		maxIdentityIds    int
+		userAssignedTypes []Type
+		expectError       bool
+	}{
+		{
+			name:              "user assigned type with no identity ids errors",
+			identityType:      TypeUserAssigned,
+			identityIdsCount:  0,
+			maxIdentityIds:    1,
+			userAssignedTypes: []Type{TypeUserAssigned},
+			expectError:       true,
+		},
+		{
+			name:              "user assigned type over the max errors",
+			identityType:      TypeUserAssigned,
+			identityIdsCount:  2,
+			maxIdentityIds:    1,
+			userAssignedTypes: []Type{TypeUserAssigned},
+			expectError:       true,
+		},
+		{
+			name:              "user assigned type within the max is valid",
+			identityType:      TypeUserAssigned,
+			identityIdsCount:  1,
+			maxIdentityIds:    1,
+			userAssignedTypes: []Type{TypeUserAssigned},
+			expectError:       false,
+		},
+		{
+			name:              "non user assigned type with no identity ids is valid",
+			identityType:      TypeSystemAssigned,
+			identityIdsCount:  0,
+			maxIdentityIds:    1,
+			userAssignedTypes: []Type{TypeUserAssigned},
+			expectError:       false,
+		},
+		{
+			name:              "non user assigned type with identity ids errors",
+			identityType:      TypeSystemAssigned,
+			identityIdsCount:  1,
+			maxIdentityIds:    1,
+			userAssignedTypes: []Type{TypeUserAssigned},
+			expectError:       true,
+		},
+		{
+			name:              "unbounded max allows any number of identity ids",
+			identityType:      TypeUserAssigned,
+			identityIdsCount:  10,
+			maxIdentityIds:    unboundedIdentityIds,
+			userAssignedTypes: []Type{TypeUserAssigned},
+			expectError:       false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateIdentityIdsCountForType(tc.identityType, tc.identityIdsCount, tc.maxIdentityIds, tc.userAssignedTypes)
+			if tc.expectError && err == nil {
+				t.Fatalf("expected an error but didn't get one")
+			}
+			if !tc.expectError && err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+		})
+	}
+}
+
+func TestValidateType(t *testing.T) {
+	validateFunc := ValidateType([]Type{TypeSystemAssigned, TypeUserAssigned})
+
+	if diags := validateFunc(string(TypeSystemAssigned), cty.Path{}); diags.HasError() {
+		t.Fatalf("expected no error for an allowed type but got %+v", diags)
+	}
+
+	if diags := validateFunc(string(TypeNone), cty.Path{}); !diags.HasError() {
+		t.Fatalf("expected an error for a type which isn't in the allowed list")
+	}
+
+	if diags := validateFunc(123, cty.Path{}); !diags.HasError() {
+		t.Fatalf("expected an error when the input isn't a string")
+	}
+}
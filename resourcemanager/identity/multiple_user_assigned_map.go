@@ -0,0 +1,204 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package identity
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"github.com/hashicorp/go-azure-helpers/resourcemanager/commonids"
+)
+
+var _ json.Marshaler = &MultipleUserAssignedMap{}
+
+// MultipleUserAssignedMap models identity blocks which only support `UserAssigned` - unlike
+// SystemOrMultipleUserAssignedMap there's no `SystemAssigned` option, so no `principal_id`/
+// `tenant_id` are exposed at the top level, and `identity_ids` is always required.
+type MultipleUserAssignedMap struct {
+	Type        Type                                   `json:"type" tfschema:"type"`
+	IdentityIds map[string]UserAssignedIdentityDetails `json:"userAssignedIdentities"`
+}
+
+func (s *MultipleUserAssignedMap) MarshalJSON() ([]byte, error) {
+	// we use a custom marshal function here since we can only send the Type / UserAssignedIdentities field
+	identityType := TypeNone
+	userAssignedIdentityIds := map[string]UserAssignedIdentityDetails{}
+
+	if s != nil && s.Type == TypeUserAssigned {
+		identityType = TypeUserAssigned
+		userAssignedIdentityIds = s.IdentityIds
+	}
+
+	out := map[string]interface{}{
+		"type":                   string(identityType),
+		"userAssignedIdentities": nil,
+	}
+	if len(userAssignedIdentityIds) > 0 {
+		out["userAssignedIdentities"] = userAssignedIdentityIds
+	}
+	return json.Marshal(out)
+}
+
+// ExpandMultipleUserAssignedMap expands the schema input into a MultipleUserAssignedMap struct
+func ExpandMultipleUserAssignedMap(input []interface{}) (*MultipleUserAssignedMap, error) {
+	identityType := TypeNone
+	identityIds := make(map[string]UserAssignedIdentityDetails, 0)
+
+	if len(input) > 0 {
+		raw := input[0].(map[string]interface{})
+		typeRaw := raw["type"].(string)
+		if typeRaw == string(TypeUserAssigned) {
+			identityType = TypeUserAssigned
+		}
+
+		identityIds = expandUserAssignedIdentityIds(identityIdsFromSchema(raw))
+	}
+
+	if identityType == TypeUserAssigned && len(identityIds) == 0 {
+		return nil, fmt.Errorf("`identity_ids` must be specified when `type` is set to %q", string(TypeUserAssigned))
+	}
+
+	identity := &MultipleUserAssignedMap{
+		Type:        identityType,
+		IdentityIds: identityIds,
+	}
+
+	return identity, nil
+}
+
+// FlattenMultipleUserAssignedMap turns a MultipleUserAssignedMap into a []interface{}
+func FlattenMultipleUserAssignedMap(input *MultipleUserAssignedMap) (*[]interface{}, error) {
+	if input == nil {
+		return &[]interface{}{}, nil
+	}
+
+	input.Type = normalizeType(input.Type)
+	if input.Type != TypeUserAssigned {
+		return &[]interface{}{}, nil
+	}
+
+	canonicalIdentityIds, err := canonicalizeIdentityIds(input.IdentityIds)
+	if err != nil {
+		return nil, err
+	}
+	input.IdentityIds = canonicalIdentityIds
+
+	return &[]interface{}{
+		map[string]interface{}{
+			"type":                 string(input.Type),
+			"identity_ids":         flattenIdentityIds(canonicalIdentityIds),
+			"identity_ids_details": flattenIdentityIdsDetails(canonicalIdentityIds),
+		},
+	}, nil
+}
+
+// ExpandMultipleUserAssignedMapFromModel expands the typed schema input into a MultipleUserAssignedMap struct
+func ExpandMultipleUserAssignedMapFromModel(input []ModelUserAssigned) (*MultipleUserAssignedMap, error) {
+	if len(input) == 0 {
+		return &MultipleUserAssignedMap{
+			Type:        TypeNone,
+			IdentityIds: nil,
+		}, nil
+	}
+
+	identity := input[0]
+
+	identityIds := expandUserAssignedIdentityIds(identity.IdentityIds)
+
+	if identity.Type == TypeUserAssigned && len(identityIds) == 0 {
+		return nil, fmt.Errorf("`identity_ids` must be specified when `type` is set to %q", string(TypeUserAssigned))
+	}
+
+	return &MultipleUserAssignedMap{
+		Type:        identity.Type,
+		IdentityIds: identityIds,
+	}, nil
+}
+
+// FlattenMultipleUserAssignedMapToModel turns a MultipleUserAssignedMap into a typed schema model
+func FlattenMultipleUserAssignedMapToModel(input *MultipleUserAssignedMap) (*[]ModelUserAssigned, error) {
+	if input == nil {
+		return &[]ModelUserAssigned{}, nil
+	}
+
+	input.Type = normalizeType(input.Type)
+	if input.Type != TypeUserAssigned {
+		return &[]ModelUserAssigned{}, nil
+	}
+
+	canonicalIdentityIds, err := canonicalizeIdentityIds(input.IdentityIds)
+	if err != nil {
+		return nil, err
+	}
+	input.IdentityIds = canonicalIdentityIds
+
+	return &[]ModelUserAssigned{
+		{
+			Type:           input.Type,
+			IdentityIds:    flattenIdentityIds(canonicalIdentityIds),
+			IdentityValues: flattenIdentityIdsDetailsToModel(canonicalIdentityIds),
+		},
+	}, nil
+}
+
+// Schema returns the Schema for the `identity` block for a Resource which only supports
+// `UserAssigned`, requiring at least one `identity_ids` value to be specified.
+// This same Schema can be used with a typed Resource by defining a field tagged `tfschema:"identity"`
+// of type `[]ModelUserAssigned`.
+func (s MultipleUserAssignedMap) Schema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"type": {
+					Type:     schema.TypeString,
+					Required: true,
+					ValidateFunc: validation.StringInSlice([]string{
+						string(TypeUserAssigned),
+					}, false),
+				},
+				"identity_ids": {
+					Type:     schema.TypeSet,
+					Required: true,
+					MinItems: 1,
+					Elem: &schema.Schema{
+						Type:         schema.TypeString,
+						ValidateFunc: commonids.ValidateUserAssignedIdentityID,
+					},
+				},
+				"identity_ids_details": identityIdsDetailsSchema(),
+			},
+		},
+	}
+}
+
+// SchemaDataSource returns the Schema for the `identity` block for a Data Source, where every field
+// (including `type` and `identity_ids`) is Computed since Data Sources cannot accept configuration.
+func (s MultipleUserAssignedMap) SchemaDataSource() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Computed: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"type": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"identity_ids": {
+					Type:     schema.TypeSet,
+					Computed: true,
+					Elem: &schema.Schema{
+						Type: schema.TypeString,
+					},
+				},
+				"identity_ids_details": identityIdsDetailsSchema(),
+			},
+		},
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package identity
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestExpandMultipleUserAssignedMapFromModel_none(t *testing.T) {
+	actual, err := ExpandMultipleUserAssignedMapFromModel(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if actual.Type != TypeNone {
+		t.Fatalf("expected TypeNone but got %q", actual.Type)
+	}
+}
+
+func TestExpandMultipleUserAssignedMapFromModel_requiresIdentityIds(t *testing.T) {
+	_, err := ExpandMultipleUserAssignedMapFromModel([]ModelUserAssigned{
+		{Type: TypeUserAssigned},
+	})
+	if err == nil {
+		t.Fatalf("expected an error since `identity_ids` is required for %q", TypeUserAssigned)
+	}
+}
+
+func TestExpandMultipleUserAssignedMapFromModel_manyIdentityIds(t *testing.T) {
+	actual, err := ExpandMultipleUserAssignedMapFromModel([]ModelUserAssigned{
+		{
+			Type:        TypeUserAssigned,
+			IdentityIds: []string{"/subscriptions/12345/id1", "/subscriptions/12345/id2"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if len(actual.IdentityIds) != 2 {
+		t.Fatalf("expected 2 identity ids but got %+v", actual.IdentityIds)
+	}
+}
+
+func TestFlattenMultipleUserAssignedMapToModel_nil(t *testing.T) {
+	actual, err := FlattenMultipleUserAssignedMapToModel(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if len(*actual) != 0 {
+		t.Fatalf("expected no items but got %+v", *actual)
+	}
+}
+
+func TestFlattenMultipleUserAssignedMapToModel_roundtrip(t *testing.T) {
+	input := &MultipleUserAssignedMap{
+		Type: TypeUserAssigned,
+		IdentityIds: map[string]UserAssignedIdentityDetails{
+			"/subscriptions/12345/resourceGroups/group1/providers/Microsoft.ManagedIdentity/userAssignedIdentities/id1": {
+				ClientId:    pointerTo("client-1"),
+				PrincipalId: pointerTo("identity-principal-1"),
+			},
+		},
+	}
+
+	actual, err := FlattenMultipleUserAssignedMapToModel(input)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if len(*actual) != 1 {
+		t.Fatalf("expected a single item but got %+v", *actual)
+	}
+
+	model := (*actual)[0]
+	if len(model.IdentityValues) != 1 {
+		t.Fatalf("expected the per-identity details to be preserved but got %+v", model.IdentityValues)
+	}
+	if model.IdentityValues[0].ClientId != "client-1" {
+		t.Fatalf("expected the identity's client_id to be preserved but got %+v", model.IdentityValues[0])
+	}
+}
+
+func TestMultipleUserAssignedMapSchema(t *testing.T) {
+	s := MultipleUserAssignedMap{}.Schema()
+
+	resource, ok := s.Elem.(*schema.Resource)
+	if !ok {
+		t.Fatalf("expected Elem to be a *schema.Resource but got %+v", s.Elem)
+	}
+	if err := resource.InternalValidate(nil, true); err != nil {
+		t.Fatalf("InternalValidate returned an error: %+v", err)
+	}
+
+	identityIds := resource.Schema["identity_ids"]
+	if !identityIds.Required {
+		t.Fatalf("expected `identity_ids` to be Required since `UserAssigned` is the only supported type")
+	}
+	if identityIds.MinItems != 1 {
+		t.Fatalf("expected `identity_ids` to require at least one value but got %+v", identityIds)
+	}
+	if identityIds.MaxItems != 0 {
+		t.Fatalf("expected `identity_ids` to allow any number of values but got MaxItems %d", identityIds.MaxItems)
+	}
+}
+
+func TestMultipleUserAssignedMapSchemaDataSource(t *testing.T) {
+	s := MultipleUserAssignedMap{}.SchemaDataSource()
+
+	resource, ok := s.Elem.(*schema.Resource)
+	if !ok {
+		t.Fatalf("expected Elem to be a *schema.Resource but got %+v", s.Elem)
+	}
+	if err := resource.InternalValidate(nil, false); err != nil {
+		t.Fatalf("InternalValidate returned an error: %+v", err)
+	}
+
+	if !resource.Schema["identity_ids"].Computed {
+		t.Fatalf("expected `identity_ids` to be Computed on a Data Source")
+	}
+}
@@ -0,0 +1,248 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package identity
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/go-azure-helpers/resourcemanager/commonids"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+var _ json.Marshaler = &SystemAndSingleUserAssignedMap{}
+
+// SystemAndSingleUserAssignedMap models identity blocks where `SystemAssigned` and `UserAssigned`
+// are both active at the same time - in this case exactly one `UserAssigned` Identity ID is
+// required, since the API only returns/accepts a single user assigned identity for this combination.
+type SystemAndSingleUserAssignedMap struct {
+	Type        Type                                   `json:"type" tfschema:"type"`
+	PrincipalId string                                 `json:"principalId" tfschema:"principal_id"`
+	TenantId    string                                 `json:"tenantId" tfschema:"tenant_id"`
+	IdentityIds map[string]UserAssignedIdentityDetails `json:"userAssignedIdentities"`
+}
+
+func (s *SystemAndSingleUserAssignedMap) MarshalJSON() ([]byte, error) {
+	// we use a custom marshal function here since we can only send the Type / UserAssignedIdentities field
+	identityType := TypeNone
+	userAssignedIdentityIds := map[string]UserAssignedIdentityDetails{}
+
+	if s != nil {
+		if s.Type == TypeSystemAssignedUserAssigned {
+			identityType = TypeSystemAssignedUserAssigned
+			userAssignedIdentityIds = s.IdentityIds
+		}
+	}
+
+	out := map[string]interface{}{
+		"type":                   string(identityType),
+		"userAssignedIdentities": nil,
+	}
+	if len(userAssignedIdentityIds) > 0 {
+		out["userAssignedIdentities"] = userAssignedIdentityIds
+	}
+	return json.Marshal(out)
+}
+
+// ExpandSystemAndSingleUserAssignedMap expands the schema input into a SystemAndSingleUserAssignedMap struct
+func ExpandSystemAndSingleUserAssignedMap(input []interface{}) (*SystemAndSingleUserAssignedMap, error) {
+	identityType := TypeNone
+	identityIds := make(map[string]UserAssignedIdentityDetails, 0)
+
+	if len(input) > 0 {
+		raw := input[0].(map[string]interface{})
+		typeRaw := raw["type"].(string)
+		if typeRaw == string(TypeSystemAssignedUserAssigned) {
+			identityType = TypeSystemAssignedUserAssigned
+		}
+
+		identityIds = expandUserAssignedIdentityIds(identityIdsFromSchema(raw))
+	}
+
+	if identityType == TypeSystemAssignedUserAssigned {
+		if len(identityIds) == 0 {
+			return nil, fmt.Errorf("`identity_ids` must be specified when `type` is set to %q", string(TypeSystemAssignedUserAssigned))
+		}
+
+		if len(identityIds) > 1 {
+			return nil, fmt.Errorf("`identity_ids` can only contain a single identity ID when `type` is set to %q", string(TypeSystemAssignedUserAssigned))
+		}
+	}
+
+	if len(identityIds) > 0 && identityType != TypeSystemAssignedUserAssigned {
+		return nil, fmt.Errorf("`identity_ids` can only be specified when `type` is set to %q", string(TypeSystemAssignedUserAssigned))
+	}
+
+	identity := &SystemAndSingleUserAssignedMap{
+		Type:        identityType,
+		IdentityIds: identityIds,
+	}
+
+	return identity, nil
+}
+
+// FlattenSystemAndSingleUserAssignedMap turns a SystemAndSingleUserAssignedMap into a []interface{}
+func FlattenSystemAndSingleUserAssignedMap(input *SystemAndSingleUserAssignedMap) (*[]interface{}, error) {
+	if input == nil {
+		return &[]interface{}{}, nil
+	}
+
+	input.Type = normalizeType(input.Type)
+	if input.Type != TypeSystemAssignedUserAssigned {
+		return &[]interface{}{}, nil
+	}
+
+	canonicalIdentityIds, err := canonicalizeIdentityIds(input.IdentityIds)
+	if err != nil {
+		return nil, err
+	}
+	input.IdentityIds = canonicalIdentityIds
+
+	return &[]interface{}{
+		map[string]interface{}{
+			"type":                 string(input.Type),
+			"identity_ids":         flattenIdentityIds(canonicalIdentityIds),
+			"identity_ids_details": flattenIdentityIdsDetails(canonicalIdentityIds),
+			"principal_id":         input.PrincipalId,
+			"tenant_id":            input.TenantId,
+		},
+	}, nil
+}
+
+// ExpandSystemAndSingleUserAssignedMapFromModel expands the typed schema input into a SystemAndSingleUserAssignedMap struct
+func ExpandSystemAndSingleUserAssignedMapFromModel(input []ModelSystemAssignedUserAssigned) (*SystemAndSingleUserAssignedMap, error) {
+	if len(input) == 0 {
+		return &SystemAndSingleUserAssignedMap{
+			Type:        TypeNone,
+			IdentityIds: nil,
+		}, nil
+	}
+
+	identity := input[0]
+
+	identityIds := expandUserAssignedIdentityIds(identity.IdentityIds)
+
+	if identity.Type == TypeSystemAssignedUserAssigned {
+		if len(identityIds) == 0 {
+			return nil, fmt.Errorf("`identity_ids` must be specified when `type` is set to %q", string(TypeSystemAssignedUserAssigned))
+		}
+
+		if len(identityIds) > 1 {
+			return nil, fmt.Errorf("`identity_ids` can only contain a single identity ID when `type` is set to %q", string(TypeSystemAssignedUserAssigned))
+		}
+	}
+
+	if len(identityIds) > 0 && identity.Type != TypeSystemAssignedUserAssigned {
+		return nil, fmt.Errorf("`identity_ids` can only be specified when `type` is set to %q", string(TypeSystemAssignedUserAssigned))
+	}
+
+	return &SystemAndSingleUserAssignedMap{
+		Type:        identity.Type,
+		IdentityIds: identityIds,
+	}, nil
+}
+
+// FlattenSystemAndSingleUserAssignedMapToModel turns a SystemAndSingleUserAssignedMap into a typed schema model
+func FlattenSystemAndSingleUserAssignedMapToModel(input *SystemAndSingleUserAssignedMap) (*[]ModelSystemAssignedUserAssigned, error) {
+	if input == nil {
+		return &[]ModelSystemAssignedUserAssigned{}, nil
+	}
+
+	input.Type = normalizeType(input.Type)
+	if input.Type != TypeSystemAssignedUserAssigned {
+		return &[]ModelSystemAssignedUserAssigned{}, nil
+	}
+
+	canonicalIdentityIds, err := canonicalizeIdentityIds(input.IdentityIds)
+	if err != nil {
+		return nil, err
+	}
+	input.IdentityIds = canonicalIdentityIds
+
+	return &[]ModelSystemAssignedUserAssigned{
+		{
+			Type:           input.Type,
+			IdentityIds:    flattenIdentityIds(canonicalIdentityIds),
+			IdentityValues: flattenIdentityIdsDetailsToModel(canonicalIdentityIds),
+			PrincipalId:    input.PrincipalId,
+			TenantId:       input.TenantId,
+		},
+	}, nil
+}
+
+// Schema returns the Schema for the `identity` block for a Resource where `SystemAssigned` and
+// `UserAssigned` are both active at once, and exactly one `identity_ids` value is required.
+// This same Schema can be used with a typed Resource by defining a field tagged `tfschema:"identity"`
+// of type `[]ModelSystemAssignedUserAssigned`.
+func (s SystemAndSingleUserAssignedMap) Schema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"type": {
+					Type:     schema.TypeString,
+					Required: true,
+					ValidateFunc: validation.StringInSlice([]string{
+						string(TypeSystemAssignedUserAssigned),
+					}, false),
+				},
+				"identity_ids": {
+					Type:     schema.TypeSet,
+					Required: true,
+					MinItems: 1,
+					MaxItems: 1,
+					Elem: &schema.Schema{
+						Type:         schema.TypeString,
+						ValidateFunc: commonids.ValidateUserAssignedIdentityID,
+					},
+				},
+				"identity_ids_details": identityIdsDetailsSchema(),
+				"principal_id": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"tenant_id": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+			},
+		},
+	}
+}
+
+// SchemaDataSource returns the Schema for the `identity` block for a Data Source, where every field
+// (including `type` and `identity_ids`) is Computed since Data Sources cannot accept configuration.
+func (s SystemAndSingleUserAssignedMap) SchemaDataSource() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Computed: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"type": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"identity_ids": {
+					Type:     schema.TypeSet,
+					Computed: true,
+					Elem: &schema.Schema{
+						Type: schema.TypeString,
+					},
+				},
+				"identity_ids_details": identityIdsDetailsSchema(),
+				"principal_id": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"tenant_id": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+			},
+		},
+	}
+}
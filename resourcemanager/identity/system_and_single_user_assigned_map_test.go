@@ -0,0 +1,145 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package identity
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestExpandSystemAndSingleUserAssignedMapFromModel_none(t *testing.T) {
+	actual, err := ExpandSystemAndSingleUserAssignedMapFromModel(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if actual.Type != TypeNone {
+		t.Fatalf("expected TypeNone but got %q", actual.Type)
+	}
+}
+
+func TestExpandSystemAndSingleUserAssignedMapFromModel_requiresIdentityIds(t *testing.T) {
+	_, err := ExpandSystemAndSingleUserAssignedMapFromModel([]ModelSystemAssignedUserAssigned{
+		{Type: TypeSystemAssignedUserAssigned},
+	})
+	if err == nil {
+		t.Fatalf("expected an error since `identity_ids` is required for %q", TypeSystemAssignedUserAssigned)
+	}
+}
+
+func TestExpandSystemAndSingleUserAssignedMapFromModel_tooManyIdentityIds(t *testing.T) {
+	_, err := ExpandSystemAndSingleUserAssignedMapFromModel([]ModelSystemAssignedUserAssigned{
+		{
+			Type:        TypeSystemAssignedUserAssigned,
+			IdentityIds: []string{"/subscriptions/12345/id1", "/subscriptions/12345/id2"},
+		},
+	})
+	if err == nil {
+		t.Fatalf("expected an error since only a single identity id is allowed for %q", TypeSystemAssignedUserAssigned)
+	}
+}
+
+func TestExpandSystemAndSingleUserAssignedMapFromModel_wrongTypeWithIdentityIds(t *testing.T) {
+	_, err := ExpandSystemAndSingleUserAssignedMapFromModel([]ModelSystemAssignedUserAssigned{
+		{Type: TypeNone, IdentityIds: []string{"/subscriptions/12345/id1"}},
+	})
+	if err == nil {
+		t.Fatalf("expected an error since `identity_ids` shouldn't be allowed when `type` is %q", TypeNone)
+	}
+}
+
+func TestExpandSystemAndSingleUserAssignedMapFromModel_valid(t *testing.T) {
+	actual, err := ExpandSystemAndSingleUserAssignedMapFromModel([]ModelSystemAssignedUserAssigned{
+		{Type: TypeSystemAssignedUserAssigned, IdentityIds: []string{"/subscriptions/12345/id1"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if len(actual.IdentityIds) != 1 {
+		t.Fatalf("expected a single identity id but got %+v", actual.IdentityIds)
+	}
+}
+
+func TestFlattenSystemAndSingleUserAssignedMapToModel_nil(t *testing.T) {
+	actual, err := FlattenSystemAndSingleUserAssignedMapToModel(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if len(*actual) != 0 {
+		t.Fatalf("expected no items but got %+v", *actual)
+	}
+}
+
+func TestFlattenSystemAndSingleUserAssignedMapToModel_roundtrip(t *testing.T) {
+	input := &SystemAndSingleUserAssignedMap{
+		Type:        TypeSystemAssignedUserAssigned,
+		PrincipalId: "principal-1",
+		TenantId:    "tenant-1",
+		IdentityIds: map[string]UserAssignedIdentityDetails{
+			"/subscriptions/12345/resourceGroups/group1/providers/Microsoft.ManagedIdentity/userAssignedIdentities/id1": {
+				ClientId:    pointerTo("client-1"),
+				PrincipalId: pointerTo("identity-principal-1"),
+			},
+		},
+	}
+
+	actual, err := FlattenSystemAndSingleUserAssignedMapToModel(input)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if len(*actual) != 1 {
+		t.Fatalf("expected a single item but got %+v", *actual)
+	}
+
+	model := (*actual)[0]
+	if len(model.IdentityValues) != 1 {
+		t.Fatalf("expected the per-identity details to be preserved but got %+v", model.IdentityValues)
+	}
+	if model.IdentityValues[0].ClientId != "client-1" {
+		t.Fatalf("expected the identity's client_id to be preserved but got %+v", model.IdentityValues[0])
+	}
+}
+
+func TestSystemAndSingleUserAssignedMapSchema(t *testing.T) {
+	s := SystemAndSingleUserAssignedMap{}.Schema()
+
+	resource, ok := s.Elem.(*schema.Resource)
+	if !ok {
+		t.Fatalf("expected Elem to be a *schema.Resource but got %+v", s.Elem)
+	}
+	if err := resource.InternalValidate(nil, true); err != nil {
+		t.Fatalf("InternalValidate returned an error: %+v", err)
+	}
+
+	identityIds := resource.Schema["identity_ids"]
+	if !identityIds.Required {
+		t.Fatalf("expected `identity_ids` to be Required since `SystemAssigned, UserAssigned` always needs exactly one identity id")
+	}
+	if identityIds.MinItems != 1 || identityIds.MaxItems != 1 {
+		t.Fatalf("expected `identity_ids` to require exactly one value but got %+v", identityIds)
+	}
+
+	if !resource.Schema["principal_id"].Computed {
+		t.Fatalf("expected `principal_id` to be Computed")
+	}
+	if !resource.Schema["tenant_id"].Computed {
+		t.Fatalf("expected `tenant_id` to be Computed")
+	}
+}
+
+func TestSystemAndSingleUserAssignedMapSchemaDataSource(t *testing.T) {
+	s := SystemAndSingleUserAssignedMap{}.SchemaDataSource()
+
+	resource, ok := s.Elem.(*schema.Resource)
+	if !ok {
+		t.Fatalf("expected Elem to be a *schema.Resource but got %+v", s.Elem)
+	}
+	if err := resource.InternalValidate(nil, false); err != nil {
+		t.Fatalf("InternalValidate returned an error: %+v", err)
+	}
+
+	if !resource.Schema["identity_ids"].Computed {
+		t.Fatalf("expected `identity_ids` to be Computed on a Data Source")
+	}
+}
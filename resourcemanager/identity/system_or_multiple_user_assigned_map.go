@@ -0,0 +1,246 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package identity
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"github.com/hashicorp/go-azure-helpers/resourcemanager/commonids"
+)
+
+var _ json.Marshaler = &SystemOrMultipleUserAssignedMap{}
+
+// SystemOrMultipleUserAssignedMap models identity blocks where `SystemAssigned` and `UserAssigned`
+// are mutually exclusive, but unlike SystemOrSingleUserAssignedMap any number of `identity_ids` can
+// be specified when `type` is `UserAssigned`.
+type SystemOrMultipleUserAssignedMap struct {
+	Type        Type                                   `json:"type" tfschema:"type"`
+	PrincipalId string                                 `json:"principalId" tfschema:"principal_id"`
+	TenantId    string                                 `json:"tenantId" tfschema:"tenant_id"`
+	IdentityIds map[string]UserAssignedIdentityDetails `json:"userAssignedIdentities"`
+}
+
+func (s *SystemOrMultipleUserAssignedMap) MarshalJSON() ([]byte, error) {
+	// we use a custom marshal function here since we can only send the Type / UserAssignedIdentities field
+	identityType := TypeNone
+	userAssignedIdentityIds := map[string]UserAssignedIdentityDetails{}
+
+	if s != nil {
+		if s.Type == TypeSystemAssigned {
+			identityType = TypeSystemAssigned
+		}
+		if s.Type == TypeUserAssigned {
+			identityType = TypeUserAssigned
+		}
+
+		if identityType != TypeNone {
+			userAssignedIdentityIds = s.IdentityIds
+		}
+	}
+
+	out := map[string]interface{}{
+		"type":                   string(identityType),
+		"userAssignedIdentities": nil,
+	}
+	if len(userAssignedIdentityIds) > 0 {
+		out["userAssignedIdentities"] = userAssignedIdentityIds
+	}
+	return json.Marshal(out)
+}
+
+// ExpandSystemOrMultipleUserAssignedMap expands the schema input into a SystemOrMultipleUserAssignedMap struct
+func ExpandSystemOrMultipleUserAssignedMap(input []interface{}) (*SystemOrMultipleUserAssignedMap, error) {
+	identityType := TypeNone
+	identityIds := make(map[string]UserAssignedIdentityDetails, 0)
+
+	if len(input) > 0 {
+		raw := input[0].(map[string]interface{})
+		typeRaw := raw["type"].(string)
+		if typeRaw == string(TypeSystemAssigned) {
+			identityType = TypeSystemAssigned
+		}
+		if typeRaw == string(TypeUserAssigned) {
+			identityType = TypeUserAssigned
+		}
+
+		identityIds = expandUserAssignedIdentityIds(identityIdsFromSchema(raw))
+	}
+
+	if identityType == TypeUserAssigned && len(identityIds) == 0 {
+		return nil, fmt.Errorf("`identity_ids` must be specified when `type` is set to %q", string(TypeUserAssigned))
+	}
+
+	if len(identityIds) > 0 && identityType == TypeSystemAssigned {
+		return nil, fmt.Errorf("`identity_ids` can only be specified when `type` is set to %q", string(TypeUserAssigned))
+	}
+
+	identity := &SystemOrMultipleUserAssignedMap{
+		Type:        identityType,
+		IdentityIds: identityIds,
+	}
+
+	return identity, nil
+}
+
+// FlattenSystemOrMultipleUserAssignedMap turns a SystemOrMultipleUserAssignedMap into a []interface{}
+func FlattenSystemOrMultipleUserAssignedMap(input *SystemOrMultipleUserAssignedMap) (*[]interface{}, error) {
+	if input == nil {
+		return &[]interface{}{}, nil
+	}
+
+	input.Type = normalizeType(input.Type)
+	if input.Type != TypeSystemAssigned && input.Type != TypeUserAssigned {
+		return &[]interface{}{}, nil
+	}
+
+	canonicalIdentityIds, err := canonicalizeIdentityIds(input.IdentityIds)
+	if err != nil {
+		return nil, err
+	}
+	input.IdentityIds = canonicalIdentityIds
+
+	return &[]interface{}{
+		map[string]interface{}{
+			"type":                 string(input.Type),
+			"identity_ids":         flattenIdentityIds(canonicalIdentityIds),
+			"identity_ids_details": flattenIdentityIdsDetails(canonicalIdentityIds),
+			"principal_id":         input.PrincipalId,
+			"tenant_id":            input.TenantId,
+		},
+	}, nil
+}
+
+// ExpandSystemOrMultipleUserAssignedMapFromModel expands the typed schema input into a SystemOrMultipleUserAssignedMap struct
+func ExpandSystemOrMultipleUserAssignedMapFromModel(input []ModelSystemAssignedUserAssigned) (*SystemOrMultipleUserAssignedMap, error) {
+	if len(input) == 0 {
+		return &SystemOrMultipleUserAssignedMap{
+			Type:        TypeNone,
+			IdentityIds: nil,
+		}, nil
+	}
+
+	identity := input[0]
+
+	identityIds := expandUserAssignedIdentityIds(identity.IdentityIds)
+
+	if identity.Type == TypeUserAssigned && len(identityIds) == 0 {
+		return nil, fmt.Errorf("`identity_ids` must be specified when `type` is set to %q", string(TypeUserAssigned))
+	}
+
+	if len(identityIds) > 0 && identity.Type == TypeSystemAssigned {
+		return nil, fmt.Errorf("`identity_ids` can only be specified when `type` is set to %q", string(TypeUserAssigned))
+	}
+
+	return &SystemOrMultipleUserAssignedMap{
+		Type:        identity.Type,
+		IdentityIds: identityIds,
+	}, nil
+}
+
+// FlattenSystemOrMultipleUserAssignedMapToModel turns a SystemOrMultipleUserAssignedMap into a typed schema model
+func FlattenSystemOrMultipleUserAssignedMapToModel(input *SystemOrMultipleUserAssignedMap) (*[]ModelSystemAssignedUserAssigned, error) {
+	if input == nil {
+		return &[]ModelSystemAssignedUserAssigned{}, nil
+	}
+
+	input.Type = normalizeType(input.Type)
+	if input.Type != TypeSystemAssigned && input.Type != TypeUserAssigned {
+		return &[]ModelSystemAssignedUserAssigned{}, nil
+	}
+
+	canonicalIdentityIds, err := canonicalizeIdentityIds(input.IdentityIds)
+	if err != nil {
+		return nil, err
+	}
+	input.IdentityIds = canonicalIdentityIds
+
+	return &[]ModelSystemAssignedUserAssigned{
+		{
+			Type:           input.Type,
+			IdentityIds:    flattenIdentityIds(canonicalIdentityIds),
+			IdentityValues: flattenIdentityIdsDetailsToModel(canonicalIdentityIds),
+			PrincipalId:    input.PrincipalId,
+			TenantId:       input.TenantId,
+		},
+	}, nil
+}
+
+// Schema returns the Schema for the `identity` block for a Resource where `SystemAssigned` and
+// `UserAssigned` are mutually exclusive, and any number of `identity_ids` can be specified.
+// This same Schema can be used with a typed Resource by defining a field tagged `tfschema:"identity"`
+// of type `[]ModelSystemAssignedUserAssigned`.
+func (s SystemOrMultipleUserAssignedMap) Schema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"type": {
+					Type:     schema.TypeString,
+					Required: true,
+					ValidateFunc: validation.StringInSlice([]string{
+						string(TypeSystemAssigned),
+						string(TypeUserAssigned),
+					}, false),
+				},
+				"identity_ids": {
+					Type:     schema.TypeSet,
+					Optional: true,
+					MinItems: 1,
+					Elem: &schema.Schema{
+						Type:         schema.TypeString,
+						ValidateFunc: commonids.ValidateUserAssignedIdentityID,
+					},
+				},
+				"identity_ids_details": identityIdsDetailsSchema(),
+				"principal_id": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"tenant_id": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+			},
+		},
+	}
+}
+
+// SchemaDataSource returns the Schema for the `identity` block for a Data Source, where every field
+// (including `type` and `identity_ids`) is Computed since Data Sources cannot accept configuration.
+func (s SystemOrMultipleUserAssignedMap) SchemaDataSource() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Computed: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"type": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"identity_ids": {
+					Type:     schema.TypeSet,
+					Computed: true,
+					Elem: &schema.Schema{
+						Type: schema.TypeString,
+					},
+				},
+				"identity_ids_details": identityIdsDetailsSchema(),
+				"principal_id": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"tenant_id": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package identity
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestExpandSystemOrMultipleUserAssignedMapFromModel_none(t *testing.T) {
+	actual, err := ExpandSystemOrMultipleUserAssignedMapFromModel(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if actual.Type != TypeNone {
+		t.Fatalf("expected TypeNone but got %q", actual.Type)
+	}
+}
+
+func TestExpandSystemOrMultipleUserAssignedMapFromModel_userAssignedRequiresIdentityIds(t *testing.T) {
+	_, err := ExpandSystemOrMultipleUserAssignedMapFromModel([]ModelSystemAssignedUserAssigned{
+		{Type: TypeUserAssigned},
+	})
+	if err == nil {
+		t.Fatalf("expected an error since `identity_ids` is required for %q", TypeUserAssigned)
+	}
+}
+
+func TestExpandSystemOrMultipleUserAssignedMapFromModel_systemAssignedWithIdentityIdsErrors(t *testing.T) {
+	_, err := ExpandSystemOrMultipleUserAssignedMapFromModel([]ModelSystemAssignedUserAssigned{
+		{Type: TypeSystemAssigned, IdentityIds: []string{"/subscriptions/12345/id1"}},
+	})
+	if err == nil {
+		t.Fatalf("expected an error since `identity_ids` shouldn't be allowed for %q", TypeSystemAssigned)
+	}
+}
+
+func TestExpandSystemOrMultipleUserAssignedMapFromModel_userAssignedManyIdentityIds(t *testing.T) {
+	actual, err := ExpandSystemOrMultipleUserAssignedMapFromModel([]ModelSystemAssignedUserAssigned{
+		{
+			Type:        TypeUserAssigned,
+			IdentityIds: []string{"/subscriptions/12345/id1", "/subscriptions/12345/id2"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if len(actual.IdentityIds) != 2 {
+		t.Fatalf("expected 2 identity ids but got %+v", actual.IdentityIds)
+	}
+}
+
+func TestFlattenSystemOrMultipleUserAssignedMapToModel_nil(t *testing.T) {
+	actual, err := FlattenSystemOrMultipleUserAssignedMapToModel(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if len(*actual) != 0 {
+		t.Fatalf("expected no items but got %+v", *actual)
+	}
+}
+
+func TestFlattenSystemOrMultipleUserAssignedMapToModel_roundtrip(t *testing.T) {
+	input := &SystemOrMultipleUserAssignedMap{
+		Type:        TypeUserAssigned,
+		PrincipalId: "principal-1",
+		TenantId:    "tenant-1",
+		IdentityIds: map[string]UserAssignedIdentityDetails{
+			"/subscriptions/12345/resourceGroups/group1/providers/Microsoft.ManagedIdentity/userAssignedIdentities/id1": {
+				ClientId:    pointerTo("client-1"),
+				PrincipalId: pointerTo("identity-principal-1"),
+			},
+			"/subscriptions/12345/resourceGroups/group1/providers/Microsoft.ManagedIdentity/userAssignedIdentities/id2": {
+				ClientId:    pointerTo("client-2"),
+				PrincipalId: pointerTo("identity-principal-2"),
+			},
+		},
+	}
+
+	actual, err := FlattenSystemOrMultipleUserAssignedMapToModel(input)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if len(*actual) != 1 {
+		t.Fatalf("expected a single item but got %+v", *actual)
+	}
+
+	model := (*actual)[0]
+	if len(model.IdentityIds) != 2 {
+		t.Fatalf("expected 2 identity ids but got %+v", model.IdentityIds)
+	}
+	if len(model.IdentityValues) != 2 {
+		t.Fatalf("expected the per-identity details to be preserved but got %+v", model.IdentityValues)
+	}
+}
+
+func TestSystemOrMultipleUserAssignedMapSchema(t *testing.T) {
+	s := SystemOrMultipleUserAssignedMap{}.Schema()
+
+	resource, ok := s.Elem.(*schema.Resource)
+	if !ok {
+		t.Fatalf("expected Elem to be a *schema.Resource but got %+v", s.Elem)
+	}
+	if err := resource.InternalValidate(nil, true); err != nil {
+		t.Fatalf("InternalValidate returned an error: %+v", err)
+	}
+
+	identityIds := resource.Schema["identity_ids"]
+	if identityIds.Required {
+		t.Fatalf("expected `identity_ids` to be Optional (since it's not required for `SystemAssigned`) but it was Required")
+	}
+	if identityIds.MaxItems != 0 {
+		t.Fatalf("expected `identity_ids` to allow any number of values but got MaxItems %d", identityIds.MaxItems)
+	}
+
+	if !resource.Schema["principal_id"].Computed {
+		t.Fatalf("expected `principal_id` to be Computed")
+	}
+	if !resource.Schema["tenant_id"].Computed {
+		t.Fatalf("expected `tenant_id` to be Computed")
+	}
+}
+
+func TestSystemOrMultipleUserAssignedMapSchemaDataSource(t *testing.T) {
+	s := SystemOrMultipleUserAssignedMap{}.SchemaDataSource()
+
+	resource, ok := s.Elem.(*schema.Resource)
+	if !ok {
+		t.Fatalf("expected Elem to be a *schema.Resource but got %+v", s.Elem)
+	}
+	if err := resource.InternalValidate(nil, false); err != nil {
+		t.Fatalf("InternalValidate returned an error: %+v", err)
+	}
+
+	if !resource.Schema["identity_ids"].Computed {
+		t.Fatalf("expected `identity_ids` to be Computed on a Data Source")
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/hashicorp/go-azure-helpers/resourcemanager/commonids"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 var _ json.Marshaler = &SystemOrSingleUserAssignedMap{}
@@ -63,12 +64,7 @@ func ExpandSystemOrSingleUserAssignedMap(input []interface{}) (*SystemOrSingleUs
 			identityType = TypeUserAssigned
 		}
 
-		identityIdsRaw := raw["identity_ids"].(*schema.Set).List()
-		for _, v := range identityIdsRaw {
-			identityIds[v.(string)] = UserAssignedIdentityDetails{
-				// intentionally empty since the expand shouldn't send these values
-			}
-		}
+		identityIds = expandUserAssignedIdentityIds(identityIdsFromSchema(raw))
 	}
 
 	if identityType == TypeUserAssigned {
@@ -104,21 +100,19 @@ func FlattenSystemOrSingleUserAssignedMap(input *SystemOrSingleUserAssignedMap)
 		return &[]interface{}{}, nil
 	}
 
-	identityIds := make([]string, 0)
-	for raw := range input.IdentityIds {
-		id, err := commonids.ParseUserAssignedIdentityIDInsensitively(raw)
-		if err != nil {
-			return nil, fmt.Errorf("parsing %q as a User Assigned Identity ID: %+v", raw, err)
-		}
-		identityIds = append(identityIds, id.ID())
+	canonicalIdentityIds, err := canonicalizeIdentityIds(input.IdentityIds)
+	if err != nil {
+		return nil, err
 	}
+	input.IdentityIds = canonicalIdentityIds
 
 	return &[]interface{}{
 		map[string]interface{}{
-			"type":         string(input.Type),
-			"identity_ids": identityIds,
-			"principal_id": input.PrincipalId,
-			"tenant_id":    input.TenantId,
+			"type":                 string(input.Type),
+			"identity_ids":         flattenIdentityIds(canonicalIdentityIds),
+			"identity_ids_details": flattenIdentityIdsDetails(canonicalIdentityIds),
+			"principal_id":         input.PrincipalId,
+			"tenant_id":            input.TenantId,
 		},
 	}, nil
 }
@@ -134,12 +128,7 @@ func ExpandSystemOrSingleUserAssignedMapFromModel(input []ModelSystemAssignedUse
 
 	identity := input[0]
 
-	identityIds := make(map[string]UserAssignedIdentityDetails, len(identity.IdentityIds))
-	for _, v := range identity.IdentityIds {
-		identityIds[v] = UserAssignedIdentityDetails{
-			// intentionally empty since the expand shouldn't send these values
-		}
-	}
+	identityIds := expandUserAssignedIdentityIds(identity.IdentityIds)
 
 	if identity.Type == TypeUserAssigned {
 		if len(identityIds) == 0 {
@@ -172,21 +161,95 @@ func FlattenSystemOrSingleUserAssignedMapToModel(input *SystemOrSingleUserAssign
 		return &[]ModelSystemAssignedUserAssigned{}, nil
 	}
 
-	identityIds := make([]string, 0)
-	for raw := range input.IdentityIds {
-		id, err := commonids.ParseUserAssignedIdentityIDInsensitively(raw)
-		if err != nil {
-			return nil, fmt.Errorf("parsing %q as a User Assigned Identity ID: %+v", raw, err)
-		}
-		identityIds = append(identityIds, id.ID())
+	canonicalIdentityIds, err := canonicalizeIdentityIds(input.IdentityIds)
+	if err != nil {
+		return nil, err
 	}
+	input.IdentityIds = canonicalIdentityIds
 
 	return &[]ModelSystemAssignedUserAssigned{
 		{
-			Type:        input.Type,
-			IdentityIds: identityIds,
-			PrincipalId: input.PrincipalId,
-			TenantId:    input.TenantId,
+			Type:           input.Type,
+			IdentityIds:    flattenIdentityIds(canonicalIdentityIds),
+			IdentityValues: flattenIdentityIdsDetailsToModel(canonicalIdentityIds),
+			PrincipalId:    input.PrincipalId,
+			TenantId:       input.TenantId,
 		},
 	}, nil
 }
+
+// Schema returns the Schema for the `identity` block for a Resource where `SystemAssigned` and
+// `UserAssigned` are mutually exclusive, and at most one `identity_ids` value can be specified.
+// This same Schema can be used with a typed Resource by defining a field tagged `tfschema:"identity"`
+// of type `[]ModelSystemAssignedUserAssigned`.
+func (s SystemOrSingleUserAssignedMap) Schema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"type": {
+					Type:     schema.TypeString,
+					Required: true,
+					ValidateFunc: validation.StringInSlice([]string{
+						string(TypeSystemAssigned),
+						string(TypeUserAssigned),
+					}, false),
+				},
+				"identity_ids": {
+					Type:     schema.TypeSet,
+					Optional: true,
+					MinItems: 1,
+					MaxItems: 1,
+					Elem: &schema.Schema{
+						Type:         schema.TypeString,
+						ValidateFunc: commonids.ValidateUserAssignedIdentityID,
+					},
+				},
+				"identity_ids_details": identityIdsDetailsSchema(),
+				"principal_id": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"tenant_id": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+			},
+		},
+	}
+}
+
+// SchemaDataSource returns the Schema for the `identity` block for a Data Source, where every field
+// (including `type` and `identity_ids`) is Computed since Data Sources cannot accept configuration.
+func (s SystemOrSingleUserAssignedMap) SchemaDataSource() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Computed: true,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"type": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"identity_ids": {
+					Type:     schema.TypeSet,
+					Computed: true,
+					Elem: &schema.Schema{
+						Type: schema.TypeString,
+					},
+				},
+				"identity_ids_details": identityIdsDetailsSchema(),
+				"principal_id": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+				"tenant_id": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+			},
+		},
+	}
+}
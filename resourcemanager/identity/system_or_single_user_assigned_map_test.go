@@ -0,0 +1,179 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package identity
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestExpandSystemOrSingleUserAssignedMapFromModel_none(t *testing.T) {
+	actual, err := ExpandSystemOrSingleUserAssignedMapFromModel(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if actual.Type != TypeNone {
+		t.Fatalf("expected TypeNone but got %q", actual.Type)
+	}
+}
+
+func TestExpandSystemOrSingleUserAssignedMapFromModel_systemAssigned(t *testing.T) {
+	actual, err := ExpandSystemOrSingleUserAssignedMapFromModel([]ModelSystemAssignedUserAssigned{
+		{Type: TypeSystemAssigned},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if actual.Type != TypeSystemAssigned {
+		t.Fatalf("expected TypeSystemAssigned but got %q", actual.Type)
+	}
+	if len(actual.IdentityIds) != 0 {
+		t.Fatalf("expected no identity ids but got %+v", actual.IdentityIds)
+	}
+}
+
+func TestExpandSystemOrSingleUserAssignedMapFromModel_systemAssignedWithIdentityIdsErrors(t *testing.T) {
+	_, err := ExpandSystemOrSingleUserAssignedMapFromModel([]ModelSystemAssignedUserAssigned{
+		{Type: TypeSystemAssigned, IdentityIds: []string{"/subscriptions/12345/id1"}},
+	})
+	if err == nil {
+		t.Fatalf("expected an error since `identity_ids` shouldn't be allowed for %q", TypeSystemAssigned)
+	}
+}
+
+func TestExpandSystemOrSingleUserAssignedMapFromModel_userAssignedRequiresIdentityIds(t *testing.T) {
+	_, err := ExpandSystemOrSingleUserAssignedMapFromModel([]ModelSystemAssignedUserAssigned{
+		{Type: TypeUserAssigned},
+	})
+	if err == nil {
+		t.Fatalf("expected an error since `identity_ids` is required for %q", TypeUserAssigned)
+	}
+}
+
+func TestExpandSystemOrSingleUserAssignedMapFromModel_userAssignedTooManyIdentityIds(t *testing.T) {
+	_, err := ExpandSystemOrSingleUserAssignedMapFromModel([]ModelSystemAssignedUserAssigned{
+		{Type: TypeUserAssigned, IdentityIds: []string{"/subscriptions/12345/id1", "/subscriptions/12345/id2"}},
+	})
+	if err == nil {
+		t.Fatalf("expected an error since only a single identity id is allowed for %q", TypeUserAssigned)
+	}
+}
+
+func TestExpandSystemOrSingleUserAssignedMapFromModel_userAssignedSingleIdentityId(t *testing.T) {
+	actual, err := ExpandSystemOrSingleUserAssignedMapFromModel([]ModelSystemAssignedUserAssigned{
+		{Type: TypeUserAssigned, IdentityIds: []string{"/subscriptions/12345/id1"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if len(actual.IdentityIds) != 1 {
+		t.Fatalf("expected a single identity id but got %+v", actual.IdentityIds)
+	}
+}
+
+func TestFlattenSystemOrSingleUserAssignedMapToModel_nil(t *testing.T) {
+	actual, err := FlattenSystemOrSingleUserAssignedMapToModel(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if len(*actual) != 0 {
+		t.Fatalf("expected no items but got %+v", *actual)
+	}
+}
+
+func TestFlattenSystemOrSingleUserAssignedMapToModel_typeNone(t *testing.T) {
+	actual, err := FlattenSystemOrSingleUserAssignedMapToModel(&SystemOrSingleUserAssignedMap{Type: TypeNone})
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if len(*actual) != 0 {
+		t.Fatalf("expected no items when `type` is %q but got %+v", TypeNone, *actual)
+	}
+}
+
+func TestFlattenSystemOrSingleUserAssignedMapToModel_roundtrip(t *testing.T) {
+	input := &SystemOrSingleUserAssignedMap{
+		Type:        TypeUserAssigned,
+		PrincipalId: "principal-1",
+		TenantId:    "tenant-1",
+		IdentityIds: map[string]UserAssignedIdentityDetails{
+			"/subscriptions/12345/resourceGroups/group1/providers/Microsoft.ManagedIdentity/userAssignedIdentities/id1": {
+				ClientId:    pointerTo("client-1"),
+				PrincipalId: pointerTo("identity-principal-1"),
+			},
+		},
+	}
+
+	actual, err := FlattenSystemOrSingleUserAssignedMapToModel(input)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if len(*actual) != 1 {
+		t.Fatalf("expected a single item but got %+v", *actual)
+	}
+
+	model := (*actual)[0]
+	if model.Type != TypeUserAssigned {
+		t.Fatalf("expected %q but got %q", TypeUserAssigned, model.Type)
+	}
+	if len(model.IdentityIds) != 1 {
+		t.Fatalf("expected a single identity id but got %+v", model.IdentityIds)
+	}
+	if len(model.IdentityValues) != 1 {
+		t.Fatalf("expected the per-identity details to be preserved but got %+v", model.IdentityValues)
+	}
+	if model.IdentityValues[0].ClientId != "client-1" {
+		t.Fatalf("expected the identity's client_id to be preserved but got %+v", model.IdentityValues[0])
+	}
+	if model.IdentityValues[0].PrincipalId != "identity-principal-1" {
+		t.Fatalf("expected the identity's principal_id to be preserved but got %+v", model.IdentityValues[0])
+	}
+}
+
+func TestSystemOrSingleUserAssignedMapSchema(t *testing.T) {
+	s := SystemOrSingleUserAssignedMap{}.Schema()
+
+	resource, ok := s.Elem.(*schema.Resource)
+	if !ok {
+		t.Fatalf("expected Elem to be a *schema.Resource but got %+v", s.Elem)
+	}
+	if err := resource.InternalValidate(nil, true); err != nil {
+		t.Fatalf("InternalValidate returned an error: %+v", err)
+	}
+
+	identityIds := resource.Schema["identity_ids"]
+	if identityIds.MinItems != 1 || identityIds.MaxItems != 1 {
+		t.Fatalf("expected `identity_ids` to allow at most a single value but got %+v", identityIds)
+	}
+	if identityIds.Required {
+		t.Fatalf("expected `identity_ids` to be Optional (since it's not required for `SystemAssigned`) but it was Required")
+	}
+
+	if !resource.Schema["principal_id"].Computed {
+		t.Fatalf("expected `principal_id` to be Computed")
+	}
+	if !resource.Schema["tenant_id"].Computed {
+		t.Fatalf("expected `tenant_id` to be Computed")
+	}
+}
+
+func TestSystemOrSingleUserAssignedMapSchemaDataSource(t *testing.T) {
+	s := SystemOrSingleUserAssignedMap{}.SchemaDataSource()
+
+	resource, ok := s.Elem.(*schema.Resource)
+	if !ok {
+		t.Fatalf("expected Elem to be a *schema.Resource but got %+v", s.Elem)
+	}
+	if err := resource.InternalValidate(nil, false); err != nil {
+		t.Fatalf("InternalValidate returned an error: %+v", err)
+	}
+
+	if !resource.Schema["type"].Computed {
+		t.Fatalf("expected `type` to be Computed on a Data Source")
+	}
+	if !resource.Schema["identity_ids"].Computed {
+		t.Fatalf("expected `identity_ids` to be Computed on a Data Source")
+	}
+}
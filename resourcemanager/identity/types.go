@@ -0,0 +1,77 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package identity
+
+import "strings"
+
+// Type describes which kind(s) of Managed Identity are enabled for a given Azure resource.
+type Type string
+
+const (
+	// TypeNone means no Managed Identity is enabled for this resource.
+	TypeNone Type = ""
+
+	// TypeSystemAssigned means a System Assigned Managed Identity is enabled for this resource.
+	TypeSystemAssigned Type = "SystemAssigned"
+
+	// TypeUserAssigned means one (or more) User Assigned Managed Identities are enabled for this resource.
+	TypeUserAssigned Type = "UserAssigned"
+
+	// TypeSystemAssignedUserAssigned means a System Assigned Managed Identity and one (or more)
+	// User Assigned Managed Identities are enabled simultaneously for this resource.
+	TypeSystemAssignedUserAssigned Type = "SystemAssigned, UserAssigned"
+)
+
+// normalizeType returns the canonical Type value for the casing/spacing variations that different
+// Azure APIs return for the same logical identity type (e.g. "SystemAssigned,UserAssigned").
+func normalizeType(input Type) Type {
+	normalized := strings.ToLower(strings.ReplaceAll(string(input), " ", ""))
+
+	switch normalized {
+	case strings.ToLower(strings.ReplaceAll(string(TypeSystemAssignedUserAssigned), " ", "")):
+		return TypeSystemAssignedUserAssigned
+	case strings.ToLower(string(TypeSystemAssigned)):
+		return TypeSystemAssigned
+	case strings.ToLower(string(TypeUserAssigned)):
+		return TypeUserAssigned
+	default:
+		return TypeNone
+	}
+}
+
+// UserAssignedIdentityDetails models the computed values Azure returns for a single User Assigned
+// Identity within an `identity` block. These fields are only ever populated by the API and should
+// never be sent on the way up.
+type UserAssignedIdentityDetails struct {
+	ClientId    *string `json:"clientId,omitempty"`
+	PrincipalId *string `json:"principalId,omitempty"`
+}
+
+// UserAssignedIdentityValue models a single User Assigned Identity attached to an `identity` block,
+// surfacing the `principal_id`/`client_id` Azure returns for that specific identity so callers don't
+// have to rely solely on the top-level `principal_id` (which only ever reflects the System Assigned
+// Identity, if any).
+type UserAssignedIdentityValue struct {
+	IdentityId  string `tfschema:"identity_id"`
+	ClientId    string `tfschema:"client_id"`
+	PrincipalId string `tfschema:"principal_id"`
+}
+
+// ModelSystemAssignedUserAssigned is the typed schema model backing the `identity` block for the
+// map types in this package which support `SystemAssigned`, `UserAssigned`, or both at once.
+type ModelSystemAssignedUserAssigned struct {
+	Type           Type                        `tfschema:"type"`
+	IdentityIds    []string                    `tfschema:"identity_ids"`
+	IdentityValues []UserAssignedIdentityValue `tfschema:"identity_ids_details"`
+	PrincipalId    string                      `tfschema:"principal_id"`
+	TenantId       string                      `tfschema:"tenant_id"`
+}
+
+// ModelUserAssigned is the typed schema model backing the `identity` block for the map types in
+// this package which only support `UserAssigned`.
+type ModelUserAssigned struct {
+	Type           Type                        `tfschema:"type"`
+	IdentityIds    []string                    `tfschema:"identity_ids"`
+	IdentityValues []UserAssignedIdentityValue `tfschema:"identity_ids_details"`
+}